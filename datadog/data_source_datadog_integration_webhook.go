@@ -0,0 +1,75 @@
+package datadog
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/zorkian/go-datadog-api"
+)
+
+func dataSourceDatadogIntegrationWebhook() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceDatadogIntegrationWebhookRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of a single webhook to filter for. If omitted, all configured webhooks are returned.",
+			},
+			"hooks": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: getWebhookSchema(),
+				},
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceDatadogIntegrationWebhookRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*datadog.Client)
+
+	integration, err := client.GetIntegrationWebhook()
+	if err != nil {
+		return fmt.Errorf("error reading the Webhook integration: %s", err.Error())
+	}
+
+	webhooks := integration.Webhooks
+	if name, ok := d.GetOk("name"); ok {
+		webhook, idx := findDatadogWebhook(webhooks, name.(string))
+		if idx == -1 {
+			return fmt.Errorf("no webhook named %q found", name.(string))
+		}
+		webhooks = []datadog.Webhook{webhook}
+	}
+
+	terraformWebhooks, err := buildTerraformWebhooks(webhooks)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set("hooks", terraformWebhooks); err != nil {
+		return err
+	}
+
+	names := make([]string, len(webhooks))
+	for i, webhook := range webhooks {
+		if webhook.Name != nil {
+			names[i] = *webhook.Name
+		}
+	}
+	if err := d.Set("names", names); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%d", schema.HashString(fmt.Sprintf("%v", names))))
+
+	return nil
+}
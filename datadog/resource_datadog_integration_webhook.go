@@ -1,7 +1,10 @@
 package datadog
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,6 +15,58 @@ import (
 
 var integrationWebhookMutex = sync.Mutex{}
 
+// datadogWebhookVariables are the variables Datadog substitutes into a webhook's
+// custom payload at delivery time. See
+// https://docs.datadoghq.com/integrations/webhooks/#variables
+var datadogWebhookVariables = map[string]bool{
+	"$AGGREG_KEY":       true,
+	"$ALERT_METRIC":     true,
+	"$ALERT_QUERY":      true,
+	"$ALERT_SCOPE":      true,
+	"$ALERT_STATUS":     true,
+	"$ALERT_TITLE":      true,
+	"$ALERT_TRANSITION": true,
+	"$DATE":             true,
+	"$EVENT_MSG":        true,
+	"$EVENT_TITLE":      true,
+	"$EVENT_TYPE":       true,
+	"$HOSTNAME":         true,
+	"$ID":               true,
+	"$LAST_UPDATED":     true,
+	"$LINK":             true,
+	"$ORG_ID":           true,
+	"$ORG_NAME":         true,
+	"$PRIORITY":         true,
+	"$SNAPSHOT":         true,
+	"$TAGS":             true,
+	"$USER":             true,
+}
+
+var datadogWebhookVariablePattern = regexp.MustCompile(`\$[A-Z][A-Z0-9_]*`)
+
+// validateCustomPayload checks that payload parses as JSON once Datadog's template
+// variables (e.g. $EVENT_TITLE) are substituted with placeholder values, and that it
+// doesn't reference a variable Datadog doesn't support.
+func validateCustomPayload(payload string) error {
+	if strings.TrimSpace(payload) == "" {
+		return nil
+	}
+
+	for _, token := range datadogWebhookVariablePattern.FindAllString(payload, -1) {
+		if !datadogWebhookVariables[token] {
+			return fmt.Errorf("custom_payload references unknown Datadog webhook variable %q", token)
+		}
+	}
+
+	rendered := datadogWebhookVariablePattern.ReplaceAllString(payload, "placeholder")
+
+	if !json.Valid([]byte(rendered)) {
+		return fmt.Errorf("custom_payload is not valid JSON once Datadog webhook variables are substituted")
+	}
+
+	return nil
+}
+
 func getWebhookSchema() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		"name": {
@@ -29,34 +84,64 @@ func getWebhookSchema() map[string]*schema.Schema {
 		"custom_payload": {
 			Type:     schema.TypeString,
 			Optional: true,
+			// Computed so that a value rendered from payload_template_file doesn't
+			// produce a diff against an unset custom_payload in config.
+			Computed:      true,
+			ConflictsWith: []string{"payload_template_file"},
+		},
+		"payload_template_file": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"custom_payload"},
+		},
+		"rendered_payload": {
+			Type:     schema.TypeString,
+			Computed: true,
 		},
 		"encode_as_form": {
 			Type:     schema.TypeBool,
 			Optional: true,
 		},
 		"headers": {
-			Type:     schema.TypeMap,
+			Type:     schema.TypeList,
 			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"value": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+				},
+			},
 		},
 	}
 }
 
+// resourceDatadogIntegrationWebhook manages the full set of configured webhooks as a
+// single resource. It always writes the complete Webhooks list, so it must not be used
+// alongside datadog_integration_webhook_hook on the same account/org: applying this
+// resource overwrites (and so deletes) any webhook created individually via that
+// per-hook resource.
 func resourceDatadogIntegrationWebhook() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceDatadogIntegrationWebhookCreate,
 		Read:   resourceDatadogIntegrationWebhookRead,
+		Update: resourceDatadogIntegrationWebhookUpdate,
 		Delete: resourceDatadogIntegrationWebhookDelete,
 		Exists: resourceDatadogIntegrationWebhookExists,
 		Importer: &schema.ResourceImporter{
 			State: resourceDatadogIntegrationWebhookImport,
 		},
+		CustomizeDiff: resourceDatadogIntegrationWebhookCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"hooks": {
 				Type:     schema.TypeList,
 				Required: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: getWebhookSchema(),
 				},
@@ -65,6 +150,27 @@ func resourceDatadogIntegrationWebhook() *schema.Resource {
 	}
 }
 
+// resourceDatadogIntegrationWebhookCustomizeDiff runs validateWebhookCustomPayloadDiff
+// against every hook at plan time, so a bad custom_payload is caught before apply.
+func resourceDatadogIntegrationWebhookCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	hooks, ok := diff.Get("hooks").([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for i, raw := range hooks {
+		hook, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := validateWebhookCustomPayloadDiff(hook); err != nil {
+			return fmt.Errorf("hooks.%d.custom_payload: %s", i, err)
+		}
+	}
+
+	return nil
+}
+
 func resourceDatadogIntegrationWebhookExists(d *schema.ResourceData, meta interface{}) (b bool, e error) {
 	// Exists - This is called to verify a resource still exists. It is called prior to Read,
 	// and lowers the burden of Read to be able to assume the resource exists.
@@ -81,17 +187,70 @@ func resourceDatadogIntegrationWebhookExists(d *schema.ResourceData, meta interf
 	return len(integration.Webhooks) > 0, nil
 }
 
-func buildDatadogHeader(headers map[string]string) string {
+// buildDatadogHeader renders the ordered "headers" list into the newline-delimited
+// "key: value" string the Datadog API expects, preserving declaration order so that
+// repeated header names and config-driven ordering don't produce spurious diffs.
+func buildDatadogHeader(headers []interface{}) string {
 	var headerList []string
 
-	for key, value := range headers {
-		headerList = append(headerList, fmt.Sprintf("%s: %s", key, value))
+	for _, raw := range headers {
+		header := raw.(map[string]interface{})
+		headerList = append(headerList, fmt.Sprintf("%s: %s", header["name"].(string), header["value"].(string)))
 	}
 
 	return strings.Join(headerList, "\n")
 }
 
-func buildDatadogWebhook(terraformWebhook map[string]interface{}) datadog.Webhook {
+// renderCustomPayload resolves the final custom payload body for a webhook: the
+// contents of payload_template_file when set (custom_payload and payload_template_file
+// are ConflictsWith siblings in the schema, so only one is ever configured), otherwise
+// the inline custom_payload.
+func renderCustomPayload(terraformWebhook map[string]interface{}) (string, error) {
+	templateFile, _ := terraformWebhook["payload_template_file"].(string)
+	payload, _ := terraformWebhook["custom_payload"].(string)
+
+	if templateFile != "" {
+		content, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading payload_template_file %q: %s", templateFile, err)
+		}
+
+		return string(content), nil
+	}
+
+	return payload, nil
+}
+
+// validateWebhookCustomPayload validates the rendered custom payload for a single
+// webhook, scoped the same way at plan time (CustomizeDiff) and apply time
+// (buildDatadogWebhook): only a custom_payload that's actually in use as a JSON body
+// needs to parse as JSON once Datadog's template variables are substituted -
+// encode_as_form sends a form-encoded (non-JSON) body, and use_custom_payload=false
+// means Datadog's default payload is used instead, so neither should be held to that
+// shape.
+func validateWebhookCustomPayload(terraformWebhook map[string]interface{}, payload string) error {
+	useCustomPayload, _ := terraformWebhook["use_custom_payload"].(bool)
+	encodeAsForm, _ := terraformWebhook["encode_as_form"].(bool)
+
+	if !useCustomPayload || encodeAsForm {
+		return nil
+	}
+
+	return validateCustomPayload(payload)
+}
+
+// validateWebhookCustomPayloadDiff renders and validates a single hook's custom
+// payload from plan-time config, for use from CustomizeDiff.
+func validateWebhookCustomPayloadDiff(terraformWebhook map[string]interface{}) error {
+	payload, err := renderCustomPayload(terraformWebhook)
+	if err != nil {
+		return err
+	}
+
+	return validateWebhookCustomPayload(terraformWebhook, payload)
+}
+
+func buildDatadogWebhook(terraformWebhook map[string]interface{}) (datadog.Webhook, error) {
 	webhook := datadog.Webhook{
 		Name: datadog.String(terraformWebhook["name"].(string)),
 		URL:  datadog.String(terraformWebhook["url"].(string)),
@@ -101,32 +260,43 @@ func buildDatadogWebhook(terraformWebhook map[string]interface{}) datadog.Webhoo
 		webhook.UseCustomPayload = datadog.String(strconv.FormatBool(attr.(bool)))
 	}
 
-	if attr, ok := terraformWebhook["custom_payload"]; ok {
-		webhook.CustomPayload = datadog.String(attr.(string))
+	payload, err := renderCustomPayload(terraformWebhook)
+	if err != nil {
+		return webhook, err
+	}
+
+	if err := validateWebhookCustomPayload(terraformWebhook, payload); err != nil {
+		return webhook, err
 	}
 
+	webhook.CustomPayload = datadog.String(payload)
+
 	if attr, ok := terraformWebhook["encode_as_form"]; ok {
 		webhook.EncodeAsForm = datadog.String(strconv.FormatBool(attr.(bool)))
 	}
 
 	if attr, ok := terraformWebhook["headers"]; ok {
-		webhook.Headers = datadog.String(buildDatadogHeader(attr.(map[string]string)))
+		webhook.Headers = datadog.String(buildDatadogHeader(attr.([]interface{})))
 	}
 
-	return webhook
+	return webhook, nil
 }
 
-func resourceDatadogIntegrationWebhookPrepareCreateRequest(d *schema.ResourceData) datadog.IntegrationWebhookRequest {
+func resourceDatadogIntegrationWebhookPrepareCreateRequest(d *schema.ResourceData) (datadog.IntegrationWebhookRequest, error) {
 
 	iwebhook := datadog.IntegrationWebhookRequest{
 		Webhooks: []datadog.Webhook{},
 	}
 
 	for _, hook := range d.Get("hooks").([]interface{}) {
-		iwebhook.Webhooks = append(iwebhook.Webhooks, buildDatadogWebhook(hook.(map[string]interface{})))
+		webhook, err := buildDatadogWebhook(hook.(map[string]interface{}))
+		if err != nil {
+			return iwebhook, err
+		}
+		iwebhook.Webhooks = append(iwebhook.Webhooks, webhook)
 	}
 
-	return iwebhook
+	return iwebhook, nil
 }
 
 func resourceDatadogIntegrationWebhookCreate(d *schema.ResourceData, meta interface{}) error {
@@ -134,7 +304,10 @@ func resourceDatadogIntegrationWebhookCreate(d *schema.ResourceData, meta interf
 	integrationWebhookMutex.Lock()
 	defer integrationWebhookMutex.Unlock()
 
-	iwebhook := resourceDatadogIntegrationWebhookPrepareCreateRequest(d)
+	iwebhook, err := resourceDatadogIntegrationWebhookPrepareCreateRequest(d)
+	if err != nil {
+		return err
+	}
 
 	if err := client.CreateIntegrationWebhook(&iwebhook); err != nil {
 		return fmt.Errorf("error creating a Webhook integration: %s", err.Error())
@@ -143,20 +316,45 @@ func resourceDatadogIntegrationWebhookCreate(d *schema.ResourceData, meta interf
 	return resourceDatadogIntegrationWebhookRead(d, meta)
 }
 
-func buildTerraformHeader(datadogHeader *string) (*map[string]string, error) {
-	terraformHeaders := map[string]string{}
+func resourceDatadogIntegrationWebhookUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*datadog.Client)
+	integrationWebhookMutex.Lock()
+	defer integrationWebhookMutex.Unlock()
+
+	iwebhook, err := resourceDatadogIntegrationWebhookPrepareCreateRequest(d)
+	if err != nil {
+		return err
+	}
+
+	if err := client.UpdateIntegrationWebhook(&iwebhook); err != nil {
+		return fmt.Errorf("error updating a Webhook integration: %s", err.Error())
+	}
+
+	return resourceDatadogIntegrationWebhookRead(d, meta)
+}
+
+// buildTerraformHeader parses the newline-delimited "key: value" string the Datadog
+// API returns back into an ordered list of {name, value} blocks. Splitting on the
+// first colon only (rather than every colon) keeps values like "Bearer x:y" or
+// "https://host/path" intact, and duplicate header names are preserved as separate
+// entries instead of colliding.
+func buildTerraformHeader(datadogHeader *string) (*[]map[string]interface{}, error) {
+	terraformHeaders := []map[string]interface{}{}
 
 	if strings.Trim(*datadogHeader, " \t\n") != "" {
 		headerStrList := strings.Split(*datadogHeader, "\n")
 
 		for _, headerStr := range headerStrList {
-			if strings.Contains(headerStr, ":") {
-				split := strings.Split(headerStr, ":")
-
-				terraformHeaders[split[0]] = strings.TrimLeft(strings.Join(split[1:], ""), " ")
-			} else {
+			if !strings.Contains(headerStr, ":") {
 				return nil, fmt.Errorf("header not correctly formatted, expected ':' in '%s'", headerStr)
 			}
+
+			split := strings.SplitN(headerStr, ":", 2)
+
+			terraformHeaders = append(terraformHeaders, map[string]interface{}{
+				"name":  split[0],
+				"value": strings.TrimSpace(split[1]),
+			})
 		}
 	}
 
@@ -181,6 +379,7 @@ func buildTerraformWebhooks(datadogWebhooks []datadog.Webhook) (*[]map[string]in
 
 		if datadogWebhook.CustomPayload != nil {
 			terraformWebhook["custom_payload"] = datadogWebhook.CustomPayload
+			terraformWebhook["rendered_payload"] = datadogWebhook.CustomPayload
 		}
 
 		if datadogWebhook.EncodeAsForm != nil {
@@ -205,6 +404,33 @@ func buildTerraformWebhooks(datadogWebhooks []datadog.Webhook) (*[]map[string]in
 	return &terraformWebhooks, nil
 }
 
+// restoreWebhookPayloadTemplateFiles copies payload_template_file from the current
+// config back into freshly read webhooks, keyed by name. The Datadog API has no
+// concept of payload_template_file - it only returns the custom_payload that
+// renderCustomPayload read from the file and sent - so buildTerraformWebhooks can't populate it, and the wholesale
+// d.Set("hooks", ...) below would otherwise reset it to "" on every refresh.
+func restoreWebhookPayloadTemplateFiles(d *schema.ResourceData, webhooks []map[string]interface{}) {
+	templateFiles := map[string]string{}
+	for _, hook := range d.Get("hooks").([]interface{}) {
+		h := hook.(map[string]interface{})
+		name, _ := h["name"].(string)
+		templateFile, _ := h["payload_template_file"].(string)
+		if name != "" && templateFile != "" {
+			templateFiles[name] = templateFile
+		}
+	}
+
+	for _, webhook := range webhooks {
+		name, _ := webhook["name"].(*string)
+		if name == nil {
+			continue
+		}
+		if templateFile, ok := templateFiles[*name]; ok {
+			webhook["payload_template_file"] = templateFile
+		}
+	}
+}
+
 func resourceDatadogIntegrationWebhookRead(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*datadog.Client)
 
@@ -218,6 +444,8 @@ func resourceDatadogIntegrationWebhookRead(d *schema.ResourceData, meta interfac
 		return err
 	}
 
+	restoreWebhookPayloadTemplateFiles(d, *terraformWebhooks)
+
 	return d.Set("hooks", terraformWebhooks)
 }
 
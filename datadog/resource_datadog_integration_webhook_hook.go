@@ -0,0 +1,221 @@
+package datadog
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/zorkian/go-datadog-api"
+)
+
+// resourceDatadogIntegrationWebhookHook manages a single webhook via a read-modify-write
+// on the shared Webhooks list, so it's safe to use from separate modules/workspaces.
+// It must not be used alongside datadog_integration_webhook on the same account/org:
+// that resource always overwrites the full Webhooks list and would delete hooks
+// managed here.
+func resourceDatadogIntegrationWebhookHook() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceDatadogIntegrationWebhookHookCreate,
+		Read:   resourceDatadogIntegrationWebhookHookRead,
+		Update: resourceDatadogIntegrationWebhookHookUpdate,
+		Delete: resourceDatadogIntegrationWebhookHookDelete,
+		Exists: resourceDatadogIntegrationWebhookHookExists,
+		Importer: &schema.ResourceImporter{
+			State: resourceDatadogIntegrationWebhookHookImport,
+		},
+		CustomizeDiff: resourceDatadogIntegrationWebhookHookCustomizeDiff,
+
+		Schema: getWebhookSchema(),
+	}
+}
+
+// resourceDatadogIntegrationWebhookHookCustomizeDiff validates custom_payload against
+// the rest of the plan-time config, so a bad payload is caught before apply.
+func resourceDatadogIntegrationWebhookHookCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	return validateWebhookCustomPayloadDiff(resourceDataToMap(diff))
+}
+
+// findDatadogWebhook returns the webhook matching name and its index in webhooks,
+// or -1 if no such webhook exists.
+func findDatadogWebhook(webhooks []datadog.Webhook, name string) (datadog.Webhook, int) {
+	for i, webhook := range webhooks {
+		if webhook.Name != nil && *webhook.Name == name {
+			return webhook, i
+		}
+	}
+
+	return datadog.Webhook{}, -1
+}
+
+func resourceDatadogIntegrationWebhookHookCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*datadog.Client)
+	integrationWebhookMutex.Lock()
+	defer integrationWebhookMutex.Unlock()
+
+	name := d.Get("name").(string)
+
+	integration, err := client.GetIntegrationWebhook()
+	var webhooks []datadog.Webhook
+	if err != nil {
+		if err.Error() != "Not Found" {
+			return fmt.Errorf("error reading the Webhook integration: %s", err.Error())
+		}
+		// No webhook integration exists yet - this is the first hook, so start from
+		// an empty list rather than dereferencing the nil integration GetIntegrationWebhook
+		// returns alongside the "Not Found" error.
+		webhooks = []datadog.Webhook{}
+	} else {
+		webhooks = integration.Webhooks
+	}
+
+	if _, idx := findDatadogWebhook(webhooks, name); idx != -1 {
+		return fmt.Errorf("a webhook named %q already exists", name)
+	}
+
+	webhook, err := buildDatadogWebhook(resourceDataToMap(d))
+	if err != nil {
+		return err
+	}
+
+	iwebhook := datadog.IntegrationWebhookRequest{
+		Webhooks: append(webhooks, webhook),
+	}
+
+	if err := client.UpdateIntegrationWebhook(&iwebhook); err != nil {
+		return fmt.Errorf("error creating a Webhook integration: %s", err.Error())
+	}
+
+	d.SetId(name)
+
+	return resourceDatadogIntegrationWebhookHookRead(d, meta)
+}
+
+func resourceDatadogIntegrationWebhookHookRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*datadog.Client)
+
+	integration, err := client.GetIntegrationWebhook()
+	if err != nil {
+		if err.Error() == "Not Found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error reading the Webhook integration: %s", err.Error())
+	}
+
+	webhook, idx := findDatadogWebhook(integration.Webhooks, d.Id())
+	if idx == -1 {
+		d.SetId("")
+		return nil
+	}
+
+	terraformWebhooks, err := buildTerraformWebhooks([]datadog.Webhook{webhook})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range (*terraformWebhooks)[0] {
+		if err := d.Set(k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceDatadogIntegrationWebhookHookUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*datadog.Client)
+	integrationWebhookMutex.Lock()
+	defer integrationWebhookMutex.Unlock()
+
+	integration, err := client.GetIntegrationWebhook()
+	if err != nil {
+		return fmt.Errorf("error reading the Webhook integration: %s", err.Error())
+	}
+
+	_, idx := findDatadogWebhook(integration.Webhooks, d.Id())
+	if idx == -1 {
+		return fmt.Errorf("webhook %q no longer exists", d.Id())
+	}
+
+	if newName := d.Get("name").(string); newName != d.Id() {
+		if _, conflictIdx := findDatadogWebhook(integration.Webhooks, newName); conflictIdx != -1 {
+			return fmt.Errorf("a webhook named %q already exists", newName)
+		}
+	}
+
+	webhook, err := buildDatadogWebhook(resourceDataToMap(d))
+	if err != nil {
+		return err
+	}
+
+	webhooks := integration.Webhooks
+	webhooks[idx] = webhook
+
+	if err := client.UpdateIntegrationWebhook(&datadog.IntegrationWebhookRequest{Webhooks: webhooks}); err != nil {
+		return fmt.Errorf("error updating a Webhook integration: %s", err.Error())
+	}
+
+	d.SetId(d.Get("name").(string))
+
+	return resourceDatadogIntegrationWebhookHookRead(d, meta)
+}
+
+func resourceDatadogIntegrationWebhookHookDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*datadog.Client)
+	integrationWebhookMutex.Lock()
+	defer integrationWebhookMutex.Unlock()
+
+	integration, err := client.GetIntegrationWebhook()
+	if err != nil {
+		return fmt.Errorf("error reading the Webhook integration: %s", err.Error())
+	}
+
+	_, idx := findDatadogWebhook(integration.Webhooks, d.Id())
+	if idx == -1 {
+		return nil
+	}
+
+	webhooks := append(integration.Webhooks[:idx], integration.Webhooks[idx+1:]...)
+
+	if err := client.UpdateIntegrationWebhook(&datadog.IntegrationWebhookRequest{Webhooks: webhooks}); err != nil {
+		return fmt.Errorf("error deleting a Webhook integration: %s", err.Error())
+	}
+
+	return nil
+}
+
+func resourceDatadogIntegrationWebhookHookExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	client := meta.(*datadog.Client)
+
+	integration, err := client.GetIntegrationWebhook()
+	if err != nil && err.Error() == "Not Found" {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	_, idx := findDatadogWebhook(integration.Webhooks, d.Id())
+	return idx != -1, nil
+}
+
+func resourceDatadogIntegrationWebhookHookImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := resourceDatadogIntegrationWebhookHookRead(d, meta); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// webhookGetter is satisfied by both *schema.ResourceData and *schema.ResourceDiff,
+// so resourceDataToMap can be reused from CustomizeDiff as well as Create/Update.
+type webhookGetter interface {
+	Get(key string) interface{}
+}
+
+// resourceDataToMap flattens the top-level attributes of getWebhookSchema()
+// into the map[string]interface{} shape buildDatadogWebhook expects.
+func resourceDataToMap(d webhookGetter) map[string]interface{} {
+	webhook := map[string]interface{}{}
+	for key := range getWebhookSchema() {
+		webhook[key] = d.Get(key)
+	}
+	return webhook
+}
@@ -0,0 +1,148 @@
+package datadog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildDatadogHeaderRoundTrip(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers []interface{}
+	}{
+		{
+			name: "colon in value",
+			headers: []interface{}{
+				map[string]interface{}{"name": "Authorization", "value": "Bearer x:y"},
+			},
+		},
+		{
+			name: "duplicate names",
+			headers: []interface{}{
+				map[string]interface{}{"name": "X-Tag", "value": "a"},
+				map[string]interface{}{"name": "X-Tag", "value": "b"},
+			},
+		},
+		{
+			name: "stable ordering",
+			headers: []interface{}{
+				map[string]interface{}{"name": "Z-Header", "value": "1"},
+				map[string]interface{}{"name": "A-Header", "value": "2"},
+				map[string]interface{}{"name": "M-Header", "value": "3"},
+			},
+		},
+		{
+			name: "url value",
+			headers: []interface{}{
+				map[string]interface{}{"name": "X-Url", "value": "https://host/path"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			datadogHeader := buildDatadogHeader(tc.headers)
+
+			terraformHeaders, err := buildTerraformHeader(&datadogHeader)
+			if err != nil {
+				t.Fatalf("buildTerraformHeader returned an error: %s", err)
+			}
+
+			if !reflect.DeepEqual(*terraformHeaders, headersAsMapSlice(tc.headers)) {
+				t.Fatalf("round trip mismatch: got %#v, want %#v", *terraformHeaders, headersAsMapSlice(tc.headers))
+			}
+		})
+	}
+}
+
+func headersAsMapSlice(headers []interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(headers))
+	for i, h := range headers {
+		out[i] = h.(map[string]interface{})
+	}
+	return out
+}
+
+func TestValidateCustomPayload(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{
+			name:    "empty payload",
+			payload: "",
+		},
+		{
+			name:    "valid JSON with known variables",
+			payload: `{"text": "$EVENT_TITLE: $EVENT_MSG", "status": "$ALERT_STATUS"}`,
+		},
+		{
+			name:    "unknown variable rejected",
+			payload: `{"text": "$NOT_A_REAL_VARIABLE"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON rejected",
+			payload: `{"text": "$EVENT_TITLE"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCustomPayload(tc.payload)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("expected no error, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestRenderCustomPayload(t *testing.T) {
+	t.Run("inline custom_payload", func(t *testing.T) {
+		payload, err := renderCustomPayload(map[string]interface{}{
+			"custom_payload": `{"text": "$EVENT_TITLE"}`,
+		})
+		if err != nil {
+			t.Fatalf("renderCustomPayload returned an error: %s", err)
+		}
+		if payload != `{"text": "$EVENT_TITLE"}` {
+			t.Fatalf("got %q, want inline custom_payload unchanged", payload)
+		}
+	})
+
+	t.Run("payload_template_file takes precedence", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "payload.tmpl")
+		if err := ioutil.WriteFile(path, []byte(`{"text": "$EVENT_MSG"}`), 0644); err != nil {
+			t.Fatalf("failed to write template file: %s", err)
+		}
+
+		payload, err := renderCustomPayload(map[string]interface{}{
+			"custom_payload":        `{"text": "inline"}`,
+			"payload_template_file": path,
+		})
+		if err != nil {
+			t.Fatalf("renderCustomPayload returned an error: %s", err)
+		}
+		if payload != `{"text": "$EVENT_MSG"}` {
+			t.Fatalf("got %q, want the template file's contents", payload)
+		}
+	})
+
+	t.Run("missing template file errors", func(t *testing.T) {
+		_, err := renderCustomPayload(map[string]interface{}{
+			"payload_template_file": filepath.Join(os.TempDir(), "does-not-exist.tmpl"),
+		})
+		if err == nil {
+			t.Fatalf("expected an error for a missing template file, got nil")
+		}
+	})
+}